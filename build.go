@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// runBuild implements `helm-walk build [flags]`, the reverse of the
+// default flatten mode: it reads the tool's own "path: value" lines from
+// stdin (or -f) and reconstructs a YAML document on stdout (or -o). This
+// closes the loop so a flatten -> edit as properties/env -> build round
+// trip is possible.
+func runBuild(args []string) {
+	fs := pflag.NewFlagSet("build", pflag.ExitOnError)
+
+	var inputFile string
+	var buildOutputFile string
+	var separator string
+	var allowTruncated bool
+
+	fs.StringVarP(&inputFile, "file", "f", "", "Flattened input to read (defaults to stdin)")
+	fs.StringVarP(&buildOutputFile, "output", "o", "", "Write YAML to a file instead of stdout")
+	fs.StringVarP(&separator, "symbol", "s", ": ", "Key - Value separator symbol (: or =) used by the input")
+	fs.BoolVar(&allowTruncated, "allow-truncated", false, "Emit empty maps/sequences for <object>/<array> sentinels instead of erroring")
+	fs.Parse(args)
+
+	var in io.Reader = os.Stdin
+	if inputFile != "" {
+		f, err := os.Open(inputFile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+		in = f
+	}
+
+	root, err := unflatten(in, separator, allowTruncated)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	encoded, err := yaml.Marshal(root)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	out := os.Stdout
+	if buildOutputFile != "" {
+		f, err := os.Create(buildOutputFile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+
+	fmt.Fprint(out, string(encoded))
+}
+
+// unflatten parses flattened "path<separator>value" lines into a yaml.Node
+// tree, reusing the same dotted-path + [index] grammar parseSetPath
+// already understands (set.go) so the two sides of the format stay in
+// sync. Insertion order is preserved since setPathValue appends new map
+// keys as they're first seen.
+func unflatten(in io.Reader, separator string, allowTruncated bool) (*yaml.Node, error) {
+	lines, err := readLines(in)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &yaml.Node{}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		path, rest, err := splitFlattenedLine(line, separator)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		var value *yaml.Node
+
+		switch rest {
+		case "|-":
+			var blockLines []string
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "  ") {
+				i++
+				blockLines = append(blockLines, strings.TrimPrefix(lines[i], "  "))
+			}
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: strings.Join(blockLines, "\n"), Style: yaml.LiteralStyle}
+
+		case "<object>":
+			if !allowTruncated {
+				return nil, fmt.Errorf("line %d: %s is truncated (<object>); rerun with --allow-truncated to emit an empty map", i+1, path)
+			}
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+		case "<array>":
+			if !allowTruncated {
+				return nil, fmt.Errorf("line %d: %s is truncated (<array>); rerun with --allow-truncated to emit an empty sequence", i+1, path)
+			}
+			value = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+
+		default:
+			if len(rest) >= 2 && strings.HasPrefix(rest, `"`) && strings.HasSuffix(rest, `"`) {
+				value = stringScalarNode(strings.ReplaceAll(rest[1:len(rest)-1], `\"`, `"`))
+			} else {
+				value = inferScalarNode(rest)
+			}
+		}
+
+		if err := setPathValue(root, path, value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+
+	return root, nil
+}
+
+// splitFlattenedLine splits a flattened line on the first occurrence of
+// separator into its path and raw value.
+func splitFlattenedLine(line, separator string) (path string, value string, err error) {
+	idx := strings.Index(line, separator)
+	if idx == -1 {
+		return "", "", fmt.Errorf("malformed line (missing %q separator): %s", separator, line)
+	}
+	return line[:idx], line[idx+len(separator):], nil
+}
+
+// readLines reads every line out of in, preserving blank lines so block
+// scalar bodies (which may contain them) line up correctly.
+func readLines(in io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
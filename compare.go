@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// compareChange is one leaf path whose value differs between the two
+// trees passed to `compare`.
+type compareChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// compareReport is the machine-readable result of `compare`: the leaf
+// paths that were added, removed, or changed between two flattened YAML
+// trees.
+type compareReport struct {
+	Added   map[string]string        `json:"added"`
+	Removed map[string]string        `json:"removed"`
+	Changed map[string]compareChange `json:"changed"`
+}
+
+// runCompare implements `helm-walk compare [flags] a.yaml b.yaml`: it
+// flattens both files with the same walk logic the default mode uses,
+// honoring --entry, --depth and --all, and reports which leaf paths were
+// added, removed or changed.
+func runCompare(args []string) {
+	fs := pflag.NewFlagSet("compare", pflag.ExitOnError)
+
+	var compareEntry string
+	var compareDepth int
+	var format string
+
+	fs.StringVarP(&compareEntry, "entry", "e", "", "Entrypoint of an object")
+	fs.IntVarP(&compareDepth, "depth", "d", -1, "Depth of walking")
+	fs.BoolVarP(&includeEmpty, "all", "A", false, "Include empty values")
+	fs.StringVar(&format, "format", "diff", "Report format: diff or json")
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) != 2 {
+		fmt.Println("usage: helm-walk compare [flags] a.yaml b.yaml")
+		fs.PrintDefaults()
+		return
+	}
+
+	left, err := flattenFileForCompare(paths[0], compareEntry, compareDepth)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	right, err := flattenFileForCompare(paths[1], compareEntry, compareDepth)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	report := diffFlattened(left, right)
+
+	if format == "json" {
+		encoded, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(string(encoded))
+		return
+	}
+
+	printCompareDiff(report)
+}
+
+// flattenFileForCompare reads a single YAML document from path, scopes it
+// to entry, and flattens it into path -> value, using each scalar's
+// normalized Value so equal values with differing YAML styles (quoted vs.
+// bare) aren't reported as changed.
+func flattenFileForCompare(path string, entry string, depth int) (map[string]string, error) {
+	yamlBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file\n" + path + ":" + err.Error() + "\n")
+	}
+
+	var yamlRoot yaml.Node
+	if err := yaml.Unmarshal(yamlBytes, &yamlRoot); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	rootNode := yamlRoot.Content[0]
+
+	entryPath := []string{}
+	if entry != "" {
+		// Resolve entry the same way findNodeByPath does (exactly one
+		// match), but keep the evaluator's concrete resolved path instead
+		// of re-splitting the raw expression, so a filter/wildcard entry
+		// like "containers[?name=nginx]" is reported as the matched
+		// "containers[0]" rather than the literal filter text.
+		matches, err := evaluatePathExpr(rootNode, entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("%s: no matches for path: %s", path, entry)
+		case 1:
+			rootNode = matches[0].node
+			entryPath = matches[0].path
+		default:
+			return nil, fmt.Errorf("%s: path %s matched %d nodes, expected exactly one", path, entry, len(matches))
+		}
+	}
+
+	flat := map[string]string{}
+	for _, e := range collectEntries(rootNode, entryPath, depth) {
+		key := strings.Join(e.Path, ".")
+		if e.Sentinel != "" {
+			flat[key] = e.Sentinel
+		} else {
+			flat[key] = e.Node.Value
+		}
+	}
+
+	return flat, nil
+}
+
+// diffFlattened compares two flattened path->value maps and buckets every
+// leaf path into added, removed or changed.
+func diffFlattened(left, right map[string]string) compareReport {
+	report := compareReport{
+		Added:   map[string]string{},
+		Removed: map[string]string{},
+		Changed: map[string]compareChange{},
+	}
+
+	for path, newValue := range right {
+		oldValue, existed := left[path]
+		if !existed {
+			report.Added[path] = newValue
+		} else if oldValue != newValue {
+			report.Changed[path] = compareChange{Old: oldValue, New: newValue}
+		}
+	}
+
+	for path, oldValue := range left {
+		if _, existsInRight := right[path]; !existsInRight {
+			report.Removed[path] = oldValue
+		}
+	}
+
+	return report
+}
+
+// printCompareDiff renders report as a unified, path-sorted diff:
+// "+ path: new", "- path: old", "~ path: old -> new".
+func printCompareDiff(report compareReport) {
+	type diffLine struct {
+		path string
+		text string
+	}
+
+	var entries []diffLine
+	for path, value := range report.Added {
+		entries = append(entries, diffLine{path, fmt.Sprintf("+ %s: %s", path, value)})
+	}
+	for path, value := range report.Removed {
+		entries = append(entries, diffLine{path, fmt.Sprintf("- %s: %s", path, value)})
+	}
+	for path, change := range report.Changed {
+		entries = append(entries, diffLine{path, fmt.Sprintf("~ %s: %s -> %s", path, change.Old, change.New)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+
+	for _, e := range entries {
+		fmt.Println(e.text)
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FlatEntry is one flattened leaf produced by collectEntries: either a
+// scalar (Node set) or a depth-truncated container (Sentinel set to
+// "<object>" or "<array>").
+type FlatEntry struct {
+	Path     []string
+	Node     *yaml.Node
+	Sentinel string
+}
+
+// Encoder renders a set of flattened entries to out. Every --output-format
+// value other than xml is backed by one of these; xml works off the node
+// tree directly (see encodeXML) since it needs to preserve structure rather
+// than flatten it.
+type Encoder interface {
+	Encode(out io.Writer, entries []FlatEntry) error
+}
+
+// encoderFor resolves the --output-format flag to its Encoder. xml is
+// handled separately by render and is not a valid value here.
+func encoderFor(format string) (Encoder, error) {
+	switch format {
+	case "", "text":
+		return textEncoder{}, nil
+	case "json":
+		return jsonEncoder{}, nil
+	case "env":
+		return envEncoder{}, nil
+	case "properties":
+		return propertiesEncoder{}, nil
+	case "csv":
+		return csvEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown --output-format %q", format)
+	}
+}
+
+// collectEntries walks node the same way the original text flattener did,
+// collecting one FlatEntry per leaf (or depth-truncated container) instead
+// of writing output directly, so every encoder can share this traversal.
+func collectEntries(node *yaml.Node, path []string, remain int) []FlatEntry {
+	var entries []FlatEntry
+
+	if !includeEmpty && isEmptyNode(node) {
+		return entries
+	}
+
+	switch node.Kind {
+
+	case yaml.MappingNode:
+		if remain == 0 {
+			return append(entries, FlatEntry{Path: path, Sentinel: "<object>"})
+		}
+
+		nextRem := remain
+		if remain > 0 {
+			nextRem = remain - 1
+		}
+
+		for i := 0; i < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			valueNode := node.Content[i+1]
+			entries = append(entries, collectEntries(valueNode, append(append([]string{}, path...), keyNode.Value), nextRem)...)
+		}
+
+	case yaml.SequenceNode:
+		if remain == 0 {
+			return append(entries, FlatEntry{Path: path, Sentinel: "<array>"})
+		}
+
+		nextRem := remain
+		if remain > 0 {
+			nextRem = remain - 1
+		}
+
+		for i, item := range node.Content {
+			p := make([]string, len(path))
+			copy(p, path)
+			p[len(p)-1] += fmt.Sprintf("[%d]", i)
+			entries = append(entries, collectEntries(item, p, nextRem)...)
+		}
+
+	default:
+		entries = append(entries, FlatEntry{Path: path, Node: node})
+	}
+
+	return entries
+}
+
+// textEncoder reproduces the tool's original key/value output: literal
+// blocks for multi-line scalars, quoting for values with YAML-sensitive
+// characters, and the <object>/<array> sentinels for depth truncation.
+type textEncoder struct{}
+
+func (textEncoder) Encode(out io.Writer, entries []FlatEntry) error {
+	for _, entry := range entries {
+		key := strings.Join(entry.Path, ".")
+
+		if entry.Sentinel != "" {
+			fmt.Fprintf(out, "%s%s%s\n", key, kvSeparator, entry.Sentinel)
+			continue
+		}
+
+		val := entry.Node.Value
+
+		if strings.Contains(val, "\n") || entry.Node.Style == yaml.LiteralStyle || entry.Node.Style == yaml.FoldedStyle {
+			fmt.Fprintf(out, "%s%s|-\n", key, kvSeparator)
+			lines := strings.Split(val, "\n")
+
+			for i, line := range lines {
+				if i == len(lines)-1 && line == "" {
+					continue
+				}
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+			continue
+		}
+
+		if strings.ContainsAny(val, ":[]{},") || strings.HasPrefix(val, " ") || strings.HasSuffix(val, " ") {
+			escaped := strings.ReplaceAll(val, "\"", "\\\"")
+			fmt.Fprintf(out, "%s%s\"%s\"\n", key, kvSeparator, escaped)
+			continue
+		}
+
+		fmt.Fprintf(out, "%s%s%s\n", key, kvSeparator, val)
+	}
+
+	return nil
+}
+
+// jsonEncoder emits an object mapping flattened paths to scalars, typing
+// values according to their YAML tag (so --set replicas=3 round-trips as a
+// JSON number, not a string).
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(out io.Writer, entries []FlatEntry) error {
+	flat := make(map[string]interface{}, len(entries))
+
+	for _, entry := range entries {
+		key := strings.Join(entry.Path, ".")
+		if entry.Sentinel != "" {
+			flat[key] = entry.Sentinel
+			continue
+		}
+		flat[key] = jsonScalar(entry.Node)
+	}
+
+	encoded, err := json.MarshalIndent(flat, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(encoded))
+	return err
+}
+
+// jsonScalar converts a scalar yaml.Node into the Go value its tag implies,
+// falling back to its raw string for anything that doesn't parse cleanly.
+func jsonScalar(node *yaml.Node) interface{} {
+	switch node.Tag {
+	case "!!int":
+		if n, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+			return n
+		}
+	case "!!float":
+		if f, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			return f
+		}
+	case "!!bool":
+		if b, err := strconv.ParseBool(node.Value); err == nil {
+			return b
+		}
+	case "!!null":
+		return nil
+	}
+
+	return node.Value
+}
+
+// propertiesEncoder emits Java-style "path=value" lines, escaping '=', ':'
+// and newlines the way java.util.Properties expects.
+type propertiesEncoder struct{}
+
+func (propertiesEncoder) Encode(out io.Writer, entries []FlatEntry) error {
+	for _, entry := range entries {
+		key := strings.Join(entry.Path, ".")
+		value := entry.Sentinel
+		if value == "" {
+			value = entry.Node.Value
+		}
+		fmt.Fprintf(out, "%s=%s\n", key, escapePropertiesValue(value))
+	}
+
+	return nil
+}
+
+func escapePropertiesValue(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '=':
+			b.WriteString(`\=`)
+		case ':':
+			b.WriteString(`\:`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// envEncoder emits shell-style "KEY=value" lines suitable for a .env file:
+// paths are upper-cased with non-identifier characters folded to '_', and
+// values are double-quoted (with escaping) whenever they need it.
+type envEncoder struct{}
+
+func (envEncoder) Encode(out io.Writer, entries []FlatEntry) error {
+	for _, entry := range entries {
+		key := envKey(entry.Path)
+		value := entry.Sentinel
+		if value == "" {
+			value = entry.Node.Value
+		}
+		fmt.Fprintf(out, "%s=%s\n", key, escapeEnvValue(value))
+	}
+
+	return nil
+}
+
+func envKey(path []string) string {
+	joined := strings.ToUpper(strings.Join(path, "_"))
+
+	var b strings.Builder
+	for _, r := range joined {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func escapeEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\n\"'#=") {
+		return value
+	}
+
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	escaped = strings.ReplaceAll(escaped, "\n", `\n`)
+	return `"` + escaped + `"`
+}
+
+// csvEncoder emits "path,value" rows (with a header) using RFC 4180
+// quoting via the standard library's csv writer.
+type csvEncoder struct{}
+
+func (csvEncoder) Encode(out io.Writer, entries []FlatEntry) error {
+	writer := csv.NewWriter(out)
+
+	if err := writer.Write([]string{"path", "value"}); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		key := strings.Join(entry.Path, ".")
+		value := entry.Sentinel
+		if value == "" {
+			value = entry.Node.Value
+		}
+		if err := writer.Write([]string{key, value}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// encodeXML round-trips node as XML, deriving element names from map keys
+// and wrapping sequence elements in <item>. Unlike the other formats it
+// walks the node tree directly rather than going through collectEntries,
+// since XML needs to preserve structure rather than flatten it. The root
+// element is named after the last segment of path (the entry that scoped
+// node), falling back to "root" when there is no entry.
+func encodeXML(out io.Writer, node *yaml.Node, path []string, remain int) error {
+	rootName := "root"
+	if len(path) > 0 {
+		rootName = path[len(path)-1]
+	}
+
+	fmt.Fprint(out, xml.Header)
+	if err := writeXMLElement(out, rootName, node, remain, 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+func writeXMLElement(out io.Writer, name string, node *yaml.Node, remain int, indent int) error {
+	if !includeEmpty && isEmptyNode(node) {
+		return nil
+	}
+
+	pad := strings.Repeat("  ", indent)
+	name = xmlSafeName(name)
+
+	switch node.Kind {
+
+	case yaml.MappingNode:
+		if remain == 0 {
+			fmt.Fprintf(out, "%s<%s><object/></%s>\n", pad, name, name)
+			return nil
+		}
+
+		nextRem := remain
+		if remain > 0 {
+			nextRem = remain - 1
+		}
+
+		fmt.Fprintf(out, "%s<%s>\n", pad, name)
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if err := writeXMLElement(out, key, node.Content[i+1], nextRem, indent+1); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "%s</%s>\n", pad, name)
+
+	case yaml.SequenceNode:
+		if remain == 0 {
+			fmt.Fprintf(out, "%s<%s><array/></%s>\n", pad, name, name)
+			return nil
+		}
+
+		nextRem := remain
+		if remain > 0 {
+			nextRem = remain - 1
+		}
+
+		fmt.Fprintf(out, "%s<%s>\n", pad, name)
+		for _, item := range node.Content {
+			if err := writeXMLElement(out, "item", item, nextRem, indent+1); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "%s</%s>\n", pad, name)
+
+	default:
+		var buf bytes.Buffer
+		if err := xml.EscapeText(&buf, []byte(node.Value)); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s<%s>%s</%s>\n", pad, name, buf.String(), name)
+	}
+
+	return nil
+}
+
+// xmlSafeName turns an arbitrary YAML map key into a valid XML element
+// name: invalid characters are folded to '_', and a name starting with a
+// digit is prefixed with '_'.
+func xmlSafeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		valid := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_' || r == '-' ||
+			(r >= '0' && r <= '9')
+		if valid {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+
+	safe := b.String()
+	if safe == "" || (safe[0] >= '0' && safe[0] <= '9') {
+		safe = "_" + safe
+	}
+	return safe
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// document is one YAML document read off the input stream, tagged with its
+// position across the whole (possibly multi-file, multi-document) input so
+// --doc-index and the "#N" stdout markers stay stable regardless of how
+// many files or documents came before it.
+type document struct {
+	index int
+	node  *yaml.Node
+}
+
+// loadDocuments expands every file pattern (a plain path or a glob, e.g.
+// "charts/**/*.yaml") to a de-duplicated list of files (sorted within each
+// pattern, patterns in the order given), then
+// decodes every "---"-separated document out of each file in order,
+// returning them as one globally-indexed list.
+func loadDocuments(patterns []string) ([]document, error) {
+	paths, err := resolveFilePaths(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []document
+	for _, path := range paths {
+		nodes, err := decodeDocuments(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			documents = append(documents, document{index: len(documents), node: node})
+		}
+	}
+
+	return documents, nil
+}
+
+// resolveFilePaths expands every pattern via doublestar (which also
+// supports recursive "**" globs, unlike the standard library's
+// filepath.Glob) and returns the combined, de-duplicated matches: each
+// pattern's matches are sorted, and patterns keep the order they were given.
+// A match that turns out to be a directory (e.g. -f charts/) is expanded
+// to the *.yaml/*.yml files directly inside it, rather than being handed
+// to decodeDocuments, which can't read a directory.
+func resolveFilePaths(patterns []string) ([]string, error) {
+	seen := map[string]bool{}
+	var paths []string
+
+	for _, pattern := range patterns {
+		matches, err := doublestar.FilepathGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files matched %q", pattern)
+		}
+
+		sort.Strings(matches)
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %q: %w", match, err)
+			}
+
+			if info.IsDir() {
+				dirPaths, err := resolveDirPaths(match)
+				if err != nil {
+					return nil, err
+				}
+				for _, dirPath := range dirPaths {
+					if !seen[dirPath] {
+						seen[dirPath] = true
+						paths = append(paths, dirPath)
+					}
+				}
+				continue
+			}
+
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// resolveDirPaths expands a directory match to the *.yaml/*.yml files
+// directly inside it (not recursing into subdirectories, since a pattern
+// that wants recursion can already ask for it with "**").
+func resolveDirPaths(dir string) ([]string, error) {
+	var paths []string
+	for _, ext := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, ext))
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", ext, err)
+		}
+		paths = append(paths, matches...)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("directory %q contains no .yaml or .yml files", dir)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// selectDocument returns the documents whose global index matches want.
+func selectDocument(documents []document, want int) []document {
+	var selected []document
+	for _, doc := range documents {
+		if doc.index == want {
+			selected = append(selected, doc)
+		}
+	}
+	return selected
+}
+
+// openDocumentOutput resolves the writer for one document's output. With
+// -o unset, every document shares os.Stdout. With -o set, a multi-document
+// run writes "outputFile.N" per document instead of overwriting a single
+// file; a single document keeps writing straight to outputFile.
+func openDocumentOutput(index int, multiplex bool) (io.Writer, func(), error) {
+	if outputFile == "" {
+		return os.Stdout, nil, nil
+	}
+
+	target := outputFile
+	if multiplex {
+		target = fmt.Sprintf("%s.%d", outputFile, index)
+	}
+
+	f, err := os.Create(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, func() { f.Close() }, nil
+}
+
+// decodeDocuments reads every "---"-separated document out of path, in
+// order, using yaml.Decoder so multi-document manifests (common in
+// rendered Helm charts) are all picked up rather than just the first.
+func decodeDocuments(path string) ([]*yaml.Node, error) {
+	yamlFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file\n" + path + ":" + err.Error() + "\n")
+	}
+	defer yamlFile.Close()
+
+	decoder := yaml.NewDecoder(yamlFile)
+
+	var nodes []*yaml.Node
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		if len(doc.Content) == 0 {
+			continue // empty document, e.g. a lone leading "---"
+		}
+		nodes = append(nodes, doc.Content[0])
+	}
+
+	return nodes, nil
+}
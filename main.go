@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/spf13/pflag"
@@ -15,53 +14,35 @@ import (
 var (
 	help bool
 	entry string
-	file string
+	files []string
 	outputFile string
 	depth int
 	includeEmpty bool
 	kvSeparator string
+	outputFormat string
+	docIndex int
 )
 
-func findNodeByPath(node *yaml.Node, entrypoint string) (*yaml.Node, error) {
-	// get hierarchical segments
-	parts := strings.Split(entrypoint, ".")
-	current := node
-
-	for _, part := range parts {
-
-		// list index: containers[0]
-		if strings.Contains(part, "[") {
-			// extract name and the index between '[' and ']'
-			name := part[:strings.Index(part, "[")]
-			indexString := part[strings.Index(part, "[") + 1:strings.Index(part, "]")]
-			index, _ := strconv.Atoi(indexString)
-
-			// child object
-			child := getMapValue(current, name)
-			if child == nil {
-				return nil, fmt.Errorf("key %s not found", name)
-			}
-
-			// ensure list exists
-			if child.Kind != yaml.SequenceNode || index >= len(child.Content) {
-				return nil, fmt.Errorf("index [%d] out of range for %s", index, name)
-			}
-
-			// move deeper into the list element
-			current = child.Content[index]
-			continue
-		}
-
-		// regular map key, no list
-        next := getMapValue(current, part)
-        if next == nil {
-            return nil, fmt.Errorf("invalid format: %s", entrypoint)
-        }
-
-		current = next
+// findNodeByPath resolves a single, non-wildcard path such as
+// "spec.containers[0].image" to exactly one node, rather than returning
+// evaluatePathExpr's full match list (compare.go applies the same
+// exactly-one rule inline so it can keep the matched path); a path
+// expression that legitimately matches more than one node (e.g.
+// "containers[*].image") is an error here.
+func findNodeByPath(root *yaml.Node, entrypoint string) (*yaml.Node, error) {
+	matches, err := evaluatePathExpr(root, entrypoint)
+	if err != nil {
+		return nil, err
 	}
 
-	return current, nil
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no matches for path: %s", entrypoint)
+	case 1:
+		return matches[0].node, nil
+	default:
+		return nil, fmt.Errorf("path %s matched %d nodes, expected exactly one", entrypoint, len(matches))
+	}
 }
 
 // mapping node: get value for key
@@ -86,11 +67,16 @@ func getMapValue(node *yaml.Node, key string) *yaml.Node {
 func prepareCliFlags() {
 	pflag.BoolVarP(&help, "help", "h", false, "Print help")
 	pflag.StringVarP(&entry, "entry", "e", "", "Entrypoint of an object")
-	pflag.StringVarP(&file, "file", "f", "", "YAML file to read regardless of kubernetes resource")
+	pflag.StringArrayVarP(&files, "file", "f", nil, "YAML file, or glob (e.g. 'charts/**/*.yaml'), to read; repeatable")
 	pflag.StringVarP(&outputFile, "output", "o", "", "Write inside file instead of stdin")
 	pflag.IntVarP(&depth, "depth", "d", -1, "Depth of walking")
 	pflag.BoolVarP(&includeEmpty, "all", "A", false, "Include empty values")
 	pflag.StringVarP(&kvSeparator, "symbol", "s", ": ", "Key - Value separator symbol (: or =)")
+	pflag.StringArrayVar(&setValues, "set", nil, "Set values on the tree, e.g. --set containers[0].image=nginx")
+	pflag.StringArrayVar(&setStrings, "set-string", nil, "Set STRING values on the tree, without type inference")
+	pflag.StringArrayVar(&setFiles, "set-file", nil, "Set values on the tree from file contents, e.g. --set-file config=./config.txt")
+	pflag.StringVar(&outputFormat, "output-format", "text", "Output format: text, json, xml, env, properties or csv")
+	pflag.IntVar(&docIndex, "doc-index", -1, "Select a single document (0-based) out of a multi-document stream; default processes all")
 	pflag.Parse()
 }
 
@@ -105,78 +91,21 @@ func isEmptyNode(node *yaml.Node) bool {
     }
 }
 
-func walk(node *yaml.Node, path []string, out io.Writer, remain int) {
-
-	// Node is empty, do not include empty values
-	if !includeEmpty && isEmptyNode(node) {
-		return
+// render flattens node starting at path and writes it to out using the
+// encoder selected by --output-format (text by default).
+func render(node *yaml.Node, path []string, out io.Writer, remain int) error {
+	if outputFormat == "xml" {
+		return encodeXML(out, node, path, remain)
 	}
 
-	switch node.Kind {
-
-	case yaml.MappingNode: // YAML object
-		if remain == 0 {
-			fmt.Fprintf(out, "%s%s<object>\n", strings.Join(path, "."), kvSeparator)
-			return
-		}
-
-		nextRem := remain
-		if remain > 0 {
-			nextRem = remain - 1
-		}
-
-		for i := 0; i < len(node.Content); i += 2 {
-			keyNode := node.Content[i]
-			valueNode := node.Content[i+1]
-			walk(valueNode, append(path, keyNode.Value), out, nextRem)
-		}
-
-	case yaml.SequenceNode: // YAML list: arr[0], arr[1], ...
-		if remain == 0 {
-			fmt.Fprintf(out, "%s%s<array>\n", strings.Join(path, "."), kvSeparator)
-			return
-		}
-
-		nextRem := remain
-		if remain > 0 {
-			nextRem = remain - 1
-		}
-
-		for i, item := range node.Content {
-			p := make([]string, len(path))
-			copy(p, path)
-			p[len(p) - 1] += fmt.Sprintf("[%d]", i)
-			walk(item, p, out, nextRem)
-		}
-
-	default: // reached a scaler value (tail)
-		val := node.Value
-
-		// If the scalar contains newlines or was originally a block scalar, preserve it as a literal block.
-		if node.Kind == yaml.ScalarNode && (strings.Contains(val, "\n") || node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle) {
-			fmt.Fprintf(out, "%s%s|-\n", strings.Join(path, "."), kvSeparator)
-			lines := strings.Split(val, "\n")
-
-			for i, line := range lines {
-				// avoid printing an extra trailing line when Split yields a trailing empty string
-				// but keep exact line breaks otherwise
-				if i == len(lines) - 1 && line == "" {
-					continue
-				}
-				fmt.Fprintf(out, "  %s\n", line)
-			}
-			return
-		}
-
-		// For single-line scalars that include YAML-sensitive characters, emit a quoted value.
-		if strings.ContainsAny(val, ":[]{},") || strings.HasPrefix(val, " ") || strings.HasSuffix(val, " ") {
-			escaped := strings.ReplaceAll(val, "\"", "\\\"")
-			fmt.Fprintf(out, "%s%s\"%s\"\n", strings.Join(path, "."), kvSeparator, escaped)
-			return
-		}
+	entries := collectEntries(node, path, remain)
 
-		fmt.Fprintf(out, "%s%s%s\n", strings.Join(path, "."), kvSeparator, val)
+	enc, err := encoderFor(outputFormat)
+	if err != nil {
+		return err
 	}
+
+	return enc.Encode(out, entries)
 }
 
 func printUsage() {
@@ -186,6 +115,18 @@ func printUsage() {
 }
 
 func main() {
+	// "helm-walk compare a.yaml b.yaml" and "helm-walk build" are the
+	// subcommands this tool has; everything else is the default flatten
+	// behavior.
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		runCompare(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "build" {
+		runBuild(os.Args[2:])
+		return
+	}
+
 	prepareCliFlags()
 
 	if (kvSeparator != ": ") && (kvSeparator != "=") {
@@ -193,52 +134,76 @@ func main() {
 		return
 	}
 
-	entryPath := []string{}
-	if entry != "" {
-		entryPath = strings.Split(entry, ".")
+	if len(files) == 0 {
+		printUsage()
+		return
 	}
 
-	var err error
-	out := os.Stdout
+	documents, err := loadDocuments(files)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	// Create a file if -o provided
-	if outputFile != "" {
-		out, err = os.Create(outputFile)
-		if err != nil {
-			fmt.Println(err)
+	if docIndex >= 0 {
+		documents = selectDocument(documents, docIndex)
+		if len(documents) == 0 {
+			fmt.Printf("doc index %d out of range\n", docIndex)
 			return
 		}
-		defer out.Close()
 	}
 
-	// Parse YAML into yaml.Node tree
-	var yamlRoot yaml.Node
+	// A stream with more than one document gets a "#N" marker on stdout,
+	// or an "outputFile.N" per document when -o is set, so none of them
+	// silently overwrite each other.
+	multiplex := len(documents) > 1
 
-	// Read from .yaml file
-	if file != "" {
-		yamlBytes, err := os.ReadFile(file)
-		if err != nil {
-			fmt.Println("error reading file\n" + file + ":" + err.Error() + "\n")
-			return
-		}
+	if outputFormat == "xml" && multiplex {
+		fmt.Println("--output-format xml does not support multi-document input: each document would emit its own XML document")
+		return
+	}
 
-		yaml.Unmarshal(yamlBytes, &yamlRoot)
-		rootNode := yamlRoot.Content[0]
+	for _, doc := range documents {
+		rootNode := doc.node
 
-		if entry == "" {
-			walk(rootNode, entryPath, out, depth)
+		if err := applySetFlags(rootNode, setValues, setStrings, setFiles); err != nil {
+			fmt.Println(err)
 			return
 		}
 
-		rootNode, err = findNodeByPath(rootNode, entry)
+		out, closeOut, err := openDocumentOutput(doc.index, multiplex)
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
 
-		walk(rootNode, entryPath, out, depth)
-		return
-	} else {
-		printUsage()
+		if multiplex && outputFile == "" {
+			fmt.Fprintf(out, "#%d\n", doc.index)
+		}
+
+		if entry == "" {
+			if err := render(rootNode, nil, out, depth); err != nil {
+				fmt.Println(err)
+			}
+		} else {
+			matches, err := evaluatePathExpr(rootNode, entry)
+			if err != nil {
+				fmt.Println(err)
+			} else if len(matches) == 0 {
+				fmt.Printf("no matches for entry expression: %s\n", entry)
+			} else if outputFormat == "xml" && len(matches) > 1 {
+				fmt.Printf("--output-format xml does not support multi-match entry expressions: %s matched %d nodes\n", entry, len(matches))
+			} else {
+				for _, match := range matches {
+					if err := render(match.node, match.path, out, depth); err != nil {
+						fmt.Println(err)
+					}
+				}
+			}
+		}
+
+		if closeOut != nil {
+			closeOut()
+		}
 	}
 }
\ No newline at end of file
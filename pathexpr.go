@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// matchedNode is one node resolved by evaluatePathExpr, tagged with the
+// concrete dotted/indexed path it was found at (e.g.
+// "spec.containers[1].image"), suitable as a prefix for render.
+type matchedNode struct {
+	node *yaml.Node
+	path []string
+}
+
+type exprTokenKind int
+
+const (
+	tokenKey exprTokenKind = iota
+	tokenIndex
+	tokenWildcard
+	tokenFilter
+	tokenRecursive
+)
+
+// exprToken is one step of a tokenized path expression.
+type exprToken struct {
+	kind        exprTokenKind
+	key         string
+	index       int
+	filterKey   string
+	filterValue string
+}
+
+// parsePathExpr tokenizes a path expression such as
+// "spec.containers[*].image", "spec.containers[?name=nginx].ports[0]" or
+// "**.image" into key, [int], [*], [?k=v] and ** tokens.
+func parsePathExpr(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+
+	for _, component := range strings.Split(expr, ".") {
+		if component == "" {
+			return nil, fmt.Errorf("malformed expression: empty path segment in %q", expr)
+		}
+
+		if component == "**" {
+			tokens = append(tokens, exprToken{kind: tokenRecursive})
+			continue
+		}
+
+		key := component
+		var brackets []string
+		if idx := strings.Index(component, "["); idx != -1 {
+			key = component[:idx]
+			rest := component[idx:]
+			for len(rest) > 0 {
+				if rest[0] != '[' {
+					return nil, fmt.Errorf("malformed expression: unexpected %q in %q", rest, expr)
+				}
+				end := strings.Index(rest, "]")
+				if end == -1 {
+					return nil, fmt.Errorf("malformed expression: unterminated '[' in %q", expr)
+				}
+				brackets = append(brackets, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+
+		if key != "" {
+			tokens = append(tokens, exprToken{kind: tokenKey, key: key})
+		}
+
+		for _, bracket := range brackets {
+			switch {
+			case bracket == "*":
+				tokens = append(tokens, exprToken{kind: tokenWildcard})
+			case strings.HasPrefix(bracket, "?"):
+				filter := bracket[1:]
+				eq := strings.Index(filter, "=")
+				if eq == -1 {
+					return nil, fmt.Errorf("malformed expression: filter %q missing '=' in %q", bracket, expr)
+				}
+				tokens = append(tokens, exprToken{kind: tokenFilter, filterKey: filter[:eq], filterValue: filter[eq+1:]})
+			default:
+				n, err := strconv.Atoi(bracket)
+				if err != nil {
+					return nil, fmt.Errorf("malformed expression: invalid index %q in %q", bracket, expr)
+				}
+				tokens = append(tokens, exprToken{kind: tokenIndex, index: n})
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+// evaluatePathExpr resolves expr against root, returning every matching
+// node together with the concrete path it was found at. A nil error with
+// zero matches means the expression was well-formed but nothing in the
+// tree matched it; a non-nil error means the expression itself couldn't
+// be parsed.
+func evaluatePathExpr(root *yaml.Node, expr string) ([]matchedNode, error) {
+	tokens, err := parsePathExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	frontier := []matchedNode{{node: root}}
+
+	for _, tok := range tokens {
+		var next []matchedNode
+
+		switch tok.kind {
+
+		case tokenKey:
+			for _, fn := range frontier {
+				if fn.node.Kind != yaml.MappingNode {
+					continue
+				}
+				child := getMapValue(fn.node, tok.key)
+				if child == nil {
+					continue
+				}
+				next = append(next, matchedNode{node: child, path: appendKey(fn.path, tok.key)})
+			}
+
+		case tokenIndex:
+			for _, fn := range frontier {
+				if fn.node.Kind != yaml.SequenceNode || tok.index < 0 || tok.index >= len(fn.node.Content) {
+					continue
+				}
+				next = append(next, matchedNode{node: fn.node.Content[tok.index], path: appendIndex(fn.path, tok.index)})
+			}
+
+		case tokenWildcard:
+			for _, fn := range frontier {
+				switch fn.node.Kind {
+				case yaml.SequenceNode:
+					for i, item := range fn.node.Content {
+						next = append(next, matchedNode{node: item, path: appendIndex(fn.path, i)})
+					}
+				case yaml.MappingNode:
+					for i := 0; i < len(fn.node.Content); i += 2 {
+						next = append(next, matchedNode{node: fn.node.Content[i+1], path: appendKey(fn.path, fn.node.Content[i].Value)})
+					}
+				}
+			}
+
+		case tokenFilter:
+			for _, fn := range frontier {
+				if fn.node.Kind != yaml.SequenceNode {
+					continue
+				}
+				for i, item := range fn.node.Content {
+					field := getMapValue(item, tok.filterKey)
+					if field != nil && field.Value == tok.filterValue {
+						next = append(next, matchedNode{node: item, path: appendIndex(fn.path, i)})
+					}
+				}
+			}
+
+		case tokenRecursive:
+			for _, fn := range frontier {
+				next = append(next, collectDescendants(fn)...)
+			}
+		}
+
+		frontier = dedupMatches(next)
+	}
+
+	return frontier, nil
+}
+
+// collectDescendants returns fn itself plus every node reachable from it
+// via a depth-first walk, each tagged with its path relative to root. This
+// backs the "**" recursive-descent token.
+func collectDescendants(fn matchedNode) []matchedNode {
+	descendants := []matchedNode{fn}
+
+	switch fn.node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(fn.node.Content); i += 2 {
+			child := matchedNode{node: fn.node.Content[i+1], path: appendKey(fn.path, fn.node.Content[i].Value)}
+			descendants = append(descendants, collectDescendants(child)...)
+		}
+	case yaml.SequenceNode:
+		for i, item := range fn.node.Content {
+			child := matchedNode{node: item, path: appendIndex(fn.path, i)}
+			descendants = append(descendants, collectDescendants(child)...)
+		}
+	}
+
+	return descendants
+}
+
+// dedupMatches removes duplicate matches by node pointer, which "**" can
+// produce when more than one frontier node reaches the same descendant.
+func dedupMatches(matches []matchedNode) []matchedNode {
+	seen := map[*yaml.Node]bool{}
+	var deduped []matchedNode
+	for _, m := range matches {
+		if seen[m.node] {
+			continue
+		}
+		seen[m.node] = true
+		deduped = append(deduped, m)
+	}
+	return deduped
+}
+
+// appendKey appends a new path component for a map key.
+func appendKey(path []string, key string) []string {
+	p := make([]string, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, key)
+}
+
+// appendIndex folds a sequence index into the last path component (so
+// "containers", 0 becomes "containers[0]"), matching the rest of the
+// tool's path formatting.
+func appendIndex(path []string, index int) []string {
+	if len(path) == 0 {
+		return []string{fmt.Sprintf("[%d]", index)}
+	}
+	p := make([]string, len(path))
+	copy(p, path)
+	p[len(p)-1] += fmt.Sprintf("[%d]", index)
+	return p
+}
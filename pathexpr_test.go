@@ -0,0 +1,212 @@
+package main
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParsePathExpr(t *testing.T) {
+	cases := []struct {
+		name    string
+		expr    string
+		want    []exprToken
+		wantErr bool
+	}{
+		{
+			name: "plain dotted path",
+			expr: "spec.containers",
+			want: []exprToken{
+				{kind: tokenKey, key: "spec"},
+				{kind: tokenKey, key: "containers"},
+			},
+		},
+		{
+			name: "index",
+			expr: "containers[0]",
+			want: []exprToken{
+				{kind: tokenKey, key: "containers"},
+				{kind: tokenIndex, index: 0},
+			},
+		},
+		{
+			name: "wildcard",
+			expr: "containers[*].image",
+			want: []exprToken{
+				{kind: tokenKey, key: "containers"},
+				{kind: tokenWildcard},
+				{kind: tokenKey, key: "image"},
+			},
+		},
+		{
+			name: "filter",
+			expr: "containers[?name=nginx].ports[0]",
+			want: []exprToken{
+				{kind: tokenKey, key: "containers"},
+				{kind: tokenFilter, filterKey: "name", filterValue: "nginx"},
+				{kind: tokenKey, key: "ports"},
+				{kind: tokenIndex, index: 0},
+			},
+		},
+		{
+			name: "recursive descent",
+			expr: "**.image",
+			want: []exprToken{
+				{kind: tokenRecursive},
+				{kind: tokenKey, key: "image"},
+			},
+		},
+		{
+			name:    "empty segment is malformed",
+			expr:    "spec..image",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated bracket is malformed",
+			expr:    "containers[0",
+			wantErr: true,
+		},
+		{
+			name:    "non-integer index is malformed",
+			expr:    "containers[x]",
+			wantErr: true,
+		},
+		{
+			name:    "filter missing '=' is malformed",
+			expr:    "containers[?name]",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parsePathExpr(tc.expr)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parsePathExpr(%q) = %v, want error", tc.expr, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parsePathExpr(%q) returned unexpected error: %v", tc.expr, err)
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("parsePathExpr(%q) = %+v, want %+v", tc.expr, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("parsePathExpr(%q)[%d] = %+v, want %+v", tc.expr, i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func mustParseYAML(t *testing.T, doc string) *yaml.Node {
+	t.Helper()
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &root); err != nil {
+		t.Fatalf("failed to parse test YAML: %v", err)
+	}
+	return root.Content[0]
+}
+
+func TestEvaluatePathExpr(t *testing.T) {
+	doc := `
+spec:
+  containers:
+    - name: app
+      image: nginx
+    - name: sidecar
+      image: busybox
+  labels:
+    tier: backend
+`
+	root := mustParseYAML(t, doc)
+
+	cases := []struct {
+		name     string
+		expr     string
+		wantVals []string
+		wantErr  bool
+	}{
+		{
+			name:     "wildcard over a sequence",
+			expr:     "spec.containers[*].image",
+			wantVals: []string{"nginx", "busybox"},
+		},
+		{
+			name:     "filter selects a single element",
+			expr:     "spec.containers[?name=sidecar].image",
+			wantVals: []string{"busybox"},
+		},
+		{
+			name:     "filter with no matching element",
+			expr:     "spec.containers[?name=missing].image",
+			wantVals: nil,
+		},
+		{
+			name:     "recursive descent finds every image at any depth",
+			expr:     "**.image",
+			wantVals: []string{"nginx", "busybox"},
+		},
+		{
+			name:     "plain path still resolves to exactly one node",
+			expr:     "spec.containers[0].image",
+			wantVals: []string{"nginx"},
+		},
+		{
+			name:    "malformed expression is an error, not zero matches",
+			expr:    "spec.containers[?name]",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := evaluatePathExpr(root, tc.expr)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("evaluatePathExpr(%q) = %v, want error", tc.expr, matches)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("evaluatePathExpr(%q) returned unexpected error: %v", tc.expr, err)
+			}
+
+			if len(matches) != len(tc.wantVals) {
+				t.Fatalf("evaluatePathExpr(%q) returned %d matches, want %d (%+v)", tc.expr, len(matches), len(tc.wantVals), matches)
+			}
+			for i, m := range matches {
+				if m.node.Value != tc.wantVals[i] {
+					t.Errorf("evaluatePathExpr(%q)[%d].node.Value = %q, want %q", tc.expr, i, m.node.Value, tc.wantVals[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluatePathExprDedupsRecursiveMatches(t *testing.T) {
+	// "**" from the root and "**" from an already-visited subtree can
+	// both reach the same node; dedup must collapse that to one match.
+	root := mustParseYAML(t, `
+a:
+  b:
+    image: shared
+`)
+
+	matches, err := evaluatePathExpr(root, "**.**.image")
+	if err != nil {
+		t.Fatalf("evaluatePathExpr returned unexpected error: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("evaluatePathExpr(\"**.**.image\") = %d matches, want 1 (%+v)", len(matches), matches)
+	}
+}
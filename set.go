@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported --set style flags. Each is a comma-separated list of
+// path=value assignments, merged into the parsed tree before it is
+// flattened (see applySetFlags for the order they are applied in).
+var (
+	setValues     []string
+	setStrings    []string
+	setFiles      []string
+)
+
+// pathSegment is one step of a --set path: a map key, optionally followed
+// by one or more [index] selectors (e.g. "containers[0]").
+type pathSegment struct {
+	key     string
+	indices []int
+}
+
+// applySetFlags merges every --set, --set-string and --set-file override
+// into root: every --set first, then every --set-string, then every
+// --set-file, each kind in the order given on the command line, so a
+// later kind wins over an earlier one for the same path. Missing
+// intermediate maps and sequences are created as needed, mirroring the
+// merge-not-duplicate behaviour Helm uses for its own value overrides.
+func applySetFlags(root *yaml.Node, sets, strs, files []string) error {
+	for _, arg := range sets {
+		for _, assignment := range splitAssignments(arg) {
+			path, raw, err := splitAssignment(assignment)
+			if err != nil {
+				return err
+			}
+			if err := setPathValue(root, path, inferScalarNode(raw)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, arg := range strs {
+		for _, assignment := range splitAssignments(arg) {
+			path, raw, err := splitAssignment(assignment)
+			if err != nil {
+				return err
+			}
+			if err := setPathValue(root, path, stringScalarNode(raw)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, arg := range files {
+		for _, assignment := range splitAssignments(arg) {
+			path, filePath, err := splitAssignment(assignment)
+			if err != nil {
+				return err
+			}
+			contents, err := os.ReadFile(filePath)
+			if err != nil {
+				return fmt.Errorf("--set-file %s: %w", path, err)
+			}
+			if err := setPathValue(root, path, stringScalarNode(string(contents))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// splitAssignments splits a comma-separated --set argument into individual
+// "path=value" assignments, honoring backslash-escaped commas so a value
+// (or key) containing a literal comma can still be expressed.
+func splitAssignments(arg string) []string {
+	var assignments []string
+	var current strings.Builder
+	escaped := false
+
+	for _, r := range arg {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			assignments = append(assignments, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	assignments = append(assignments, current.String())
+
+	return assignments
+}
+
+// splitAssignment splits "path=value" on the first unescaped '='.
+func splitAssignment(assignment string) (path string, value string, err error) {
+	escaped := false
+	for i, r := range assignment {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '=':
+			return assignment[:i], assignment[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid --set assignment, missing '=': %s", assignment)
+}
+
+// parseSetPath tokenizes a dotted path such as "containers[0].image" or an
+// escaped key like "annotations.my\.key" into segments, respecting
+// backslash escapes for literal '.' and '[' characters inside keys.
+func parseSetPath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	var key strings.Builder
+	var indices []int
+	escaped := false
+
+	flush := func() {
+		segments = append(segments, pathSegment{key: key.String(), indices: indices})
+		key.Reset()
+		indices = nil
+	}
+
+	runes := []rune(path)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case escaped:
+			key.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			flush()
+		case r == '[':
+			end := strings.IndexRune(string(runes[i+1:]), ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated '[' in path: %s", path)
+			}
+			indexString := string(runes[i+1 : i+1+end])
+			index, convErr := strconv.Atoi(indexString)
+			if convErr != nil {
+				return nil, fmt.Errorf("invalid list index %q in path: %s", indexString, path)
+			}
+			if index < 0 {
+				return nil, fmt.Errorf("list index must be non-negative, got %d in path: %s", index, path)
+			}
+			indices = append(indices, index)
+			i += end + 1
+		default:
+			key.WriteRune(r)
+		}
+	}
+	flush()
+
+	return segments, nil
+}
+
+// setPathValue parses path and merges value into root at that location,
+// auto-creating any map or sequence nodes along the way.
+func setPathValue(root *yaml.Node, path string, value *yaml.Node) error {
+	segments, err := parseSetPath(path)
+	if err != nil {
+		return err
+	}
+
+	if root.Kind == 0 {
+		root.Kind = yaml.MappingNode
+		root.Tag = "!!map"
+	}
+
+	current := root
+	for si, seg := range segments {
+		lastSegment := si == len(segments)-1
+
+		current = getOrCreateMapChild(current, seg.key, lastSegment && len(seg.indices) == 0, value)
+
+		for ii, index := range seg.indices {
+			lastIndex := lastSegment && ii == len(seg.indices)-1
+			current = getOrCreateSeqChild(current, index, lastIndex, value)
+		}
+	}
+
+	return nil
+}
+
+// getOrCreateMapChild returns the child of parent keyed by key, creating
+// the key (and an intermediate map, or the final value) if it doesn't
+// already exist. parent is coerced into a mapping node if it wasn't one.
+func getOrCreateMapChild(parent *yaml.Node, key string, isLeaf bool, leafValue *yaml.Node) *yaml.Node {
+	if parent.Kind != yaml.MappingNode {
+		*parent = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	for i := 0; i < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			child := parent.Content[i+1]
+			if isLeaf {
+				*child = *leafValue
+			} else if child.Kind != yaml.MappingNode && child.Kind != yaml.SequenceNode {
+				*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			return child
+		}
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if isLeaf {
+		*child = *leafValue
+	}
+	parent.Content = append(parent.Content, keyNode, child)
+
+	return child
+}
+
+// getOrCreateSeqChild returns the element at index in parent, extending
+// the sequence with null nodes if index is beyond its current length.
+// parent is coerced into a sequence node if it wasn't one.
+func getOrCreateSeqChild(parent *yaml.Node, index int, isLeaf bool, leafValue *yaml.Node) *yaml.Node {
+	if parent.Kind != yaml.SequenceNode {
+		*parent = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	for len(parent.Content) <= index {
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+	}
+
+	child := parent.Content[index]
+	if isLeaf {
+		*child = *leafValue
+	} else if child.Kind != yaml.MappingNode && child.Kind != yaml.SequenceNode {
+		*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	return child
+}
+
+// inferScalarNode applies Helm's --set type inference rules directly
+// (bool/null literals, then int, then float, else string) rather than
+// running raw through the full YAML parser: a value like "Deployed: true"
+// is valid YAML for a one-key map, and parsing it as a document would
+// silently turn a plain string override into a nested object.
+func inferScalarNode(raw string) *yaml.Node {
+	switch raw {
+	case "true", "false":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: raw}
+	case "null", "~":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: raw}
+	}
+
+	if _, err := strconv.ParseFloat(raw, 64); err == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: raw}
+	}
+
+	return stringScalarNode(raw)
+}
+
+// stringScalarNode builds a plain string scalar, used by --set-string and
+// --set-file where no type inference should happen.
+func stringScalarNode(raw string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}
+}
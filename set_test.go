@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInferScalarNode(t *testing.T) {
+	cases := []struct {
+		raw     string
+		wantTag string
+		wantVal string
+	}{
+		{raw: "123", wantTag: "!!int", wantVal: "123"},
+		{raw: "-4", wantTag: "!!int", wantVal: "-4"},
+		{raw: "3.14", wantTag: "!!float", wantVal: "3.14"},
+		{raw: "true", wantTag: "!!bool", wantVal: "true"},
+		{raw: "false", wantTag: "!!bool", wantVal: "false"},
+		{raw: "null", wantTag: "!!null", wantVal: "null"},
+		{raw: "nginx", wantTag: "!!str", wantVal: "nginx"},
+		// A value that happens to be valid YAML for a one-key map must
+		// still come back as a plain string scalar, not be parsed into a
+		// nested object.
+		{raw: "Deployed: true", wantTag: "!!str", wantVal: "Deployed: true"},
+		{raw: "- item", wantTag: "!!str", wantVal: "- item"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.raw, func(t *testing.T) {
+			node := inferScalarNode(tc.raw)
+			if node.Kind != yaml.ScalarNode {
+				t.Fatalf("inferScalarNode(%q).Kind = %v, want ScalarNode", tc.raw, node.Kind)
+			}
+			if node.Tag != tc.wantTag {
+				t.Errorf("inferScalarNode(%q).Tag = %s, want %s", tc.raw, node.Tag, tc.wantTag)
+			}
+			if node.Value != tc.wantVal {
+				t.Errorf("inferScalarNode(%q).Value = %s, want %s", tc.raw, node.Value, tc.wantVal)
+			}
+		})
+	}
+}
+
+func TestParseSetPathRejectsNegativeIndex(t *testing.T) {
+	if _, err := parseSetPath("arr[-1]"); err == nil {
+		t.Fatal("parseSetPath(\"arr[-1]\") = nil error, want error")
+	}
+}
+
+func TestSetPathValue(t *testing.T) {
+	root := &yaml.Node{}
+
+	if err := setPathValue(root, "containers[0].image", stringScalarNode("nginx")); err != nil {
+		t.Fatalf("setPathValue returned unexpected error: %v", err)
+	}
+	if err := setPathValue(root, "replicas", inferScalarNode("3")); err != nil {
+		t.Fatalf("setPathValue returned unexpected error: %v", err)
+	}
+
+	image := getMapValue(root, "containers")
+	if image == nil || image.Kind != yaml.SequenceNode || len(image.Content) != 1 {
+		t.Fatalf("containers = %+v, want a one-element sequence", image)
+	}
+
+	imageValue := getMapValue(image.Content[0], "image")
+	if imageValue == nil || imageValue.Value != "nginx" {
+		t.Fatalf("containers[0].image = %+v, want \"nginx\"", imageValue)
+	}
+
+	replicas := getMapValue(root, "replicas")
+	if replicas == nil || replicas.Tag != "!!int" || replicas.Value != "3" {
+		t.Fatalf("replicas = %+v, want int 3", replicas)
+	}
+}
+
+func TestSetPathValueRejectsNegativeIndex(t *testing.T) {
+	root := &yaml.Node{}
+	if err := setPathValue(root, "arr[-1]", stringScalarNode("foo")); err == nil {
+		t.Fatal("setPathValue(\"arr[-1]\") = nil error, want error")
+	}
+}